@@ -9,10 +9,16 @@ import (
 	"math/big"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/bloxapp/ssv-rewards/pkg/models"
 	"github.com/bloxapp/ssv-rewards/pkg/rewards"
+	"github.com/bloxapp/ssv-rewards/pkg/rewards/merkle"
+	"github.com/bloxapp/ssv-rewards/pkg/rewards/remap"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/gocarina/gocsv"
 	"github.com/volatiletech/sqlboiler/v4/queries"
 	"go.uber.org/zap"
@@ -20,9 +26,10 @@ import (
 )
 
 type CalcCmd struct {
-	Dir                      string `default:"./rewards" help:"Path to save the rewards to,"`
-	PerformanceProvider      string `default:"beaconcha" help:"Performance provider to use."                                       enum:"beaconcha,e2m"`
-	MinimumDailyAttestations int    `default:"202"       help:"Minimum attestations in a day to be considered active."`
+	Dir                      string `default:"./rewards"    help:"Path to save the rewards to,"`
+	PerformanceProvider      string `default:"beaconcha"    help:"Performance provider to use."                                       enum:"beaconcha,e2m"`
+	MinimumDailyAttestations int    `default:"202"          help:"Minimum attestations in a day to be considered active."`
+	MerkleFormat             string `default:"sorted-pair"  help:"Pair-hashing layout for merkle.json."                              enum:"sorted-pair,openzeppelin"`
 
 	db *sql.DB
 }
@@ -63,79 +70,67 @@ func (c *CalcCmd) Run(logger *zap.Logger, globals *Globals) error {
 	}
 	latestValidatorPerformancePeriod := rewards.PeriodAt(state.LatestValidatorPerformance.Time)
 
-	// Select the rounds with available performance data.
+	// Select the rounds with available performance data, skipping rounds
+	// that were fully halted.
 	var completeRounds []rewards.Round
 	for _, round := range plan.Rounds {
 		if round.ETHAPR > 0 && round.SSVETH > 0 &&
 			round.Period.LastDay().Before(latestValidatorPerformancePeriod.FirstDay()) {
+			if halted, reason := plan.Halted(round.Period); halted {
+				logger.Info("Skipping halted round", zap.String("period", round.Period.String()), zap.String("reason", reason))
+				continue
+			}
 			completeRounds = append(completeRounds, round)
 		}
 	}
 
 	// Calculate rewards.
+	remapper := remap.New(plan.OwnerRemaps)
 	var byValidator []*ValidatorParticipationRound
 	var byOwner []*OwnerParticipationRound
 	var totalByValidator = map[string]*ValidatorParticipation{}
 	var totalByOwner = map[string]*OwnerParticipation{}
+	merkleOwnerIndex := newOwnerIndex()
 	for _, round := range completeRounds {
 		// Collect validator and owner participations.
 		validatorParticipations, err := c.validatorParticipations(ctx, round.Period)
 		if err != nil {
 			return fmt.Errorf("failed to get validator participations: %w", err)
 		}
-		ownerParticipations, err := c.ownerParticipations(ctx, round.Period)
-		if err != nil {
-			return fmt.Errorf("failed to get owner participations: %w", err)
-		}
+		validatorParticipations = applyOwnerRemaps(logger, plan.OwnerRemaps, remapper, round.Period, validatorParticipations)
 
-		// Calculate appropriate tier and rewards.
-		tier, err := plan.Tier(len(validatorParticipations))
-		if err != nil {
-			return fmt.Errorf("failed to get tier: %w", err)
+		var ownerParticipations []*OwnerParticipation
+		if len(plan.OwnerRemaps) > 0 {
+			// Owner-level rows from Postgres reflect on-chain ownership, not
+			// the plan's remaps, so re-derive them from the (remapped)
+			// validator-level participations instead.
+			ownerParticipations = aggregateOwnerParticipations(validatorParticipations)
+		} else {
+			ownerParticipations, err = c.ownerParticipations(ctx, round.Period)
+			if err != nil {
+				return fmt.Errorf("failed to get owner participations: %w", err)
+			}
 		}
-		dailyReward, monthlyReward, annualReward, err := plan.ValidatorRewards(round.Period, len(validatorParticipations))
+
+		// Calculate appropriate tier and rewards, and aggregate them into
+		// the running totals.
+		tier, dailyReward, monthlyReward, annualReward, err := calculateRound(
+			plan, round, validatorParticipations, ownerParticipations, totalByValidator, totalByOwner,
+		)
 		if err != nil {
-			return fmt.Errorf("failed to get reward: %w", err)
+			return fmt.Errorf("failed to calculate round %s: %w", round.Period, err)
 		}
-
-		// Attach rewards to participations.
-		ownerRewards := map[string]float64{}
-		ownerActiveDays := map[string]int{}
 		for _, participation := range validatorParticipations {
-			participation.Reward = dailyReward * float64(participation.ActiveDays)
-			ownerRewards[participation.OwnerAddress] += participation.Reward
-			ownerActiveDays[participation.OwnerAddress] += participation.ActiveDays
-
 			byValidator = append(byValidator, &ValidatorParticipationRound{
 				Period:                 round.Period,
 				ValidatorParticipation: participation,
 			})
-			if total, ok := totalByValidator[participation.PublicKey]; ok {
-				total.ActiveDays += participation.ActiveDays
-				total.Reward += participation.Reward
-			} else {
-				cpy := *participation
-				totalByValidator[participation.PublicKey] = &cpy
-			}
 		}
 		for _, participation := range ownerParticipations {
-			participation.Reward = dailyReward * float64(participation.ActiveDays)
-
-			if participation.ActiveDays != ownerActiveDays[participation.OwnerAddress] {
-				return fmt.Errorf("inconsistent active days for owner %q", participation.OwnerAddress)
-			}
-
 			byOwner = append(byOwner, &OwnerParticipationRound{
 				Period:             round.Period,
 				OwnerParticipation: participation,
 			})
-			if total, ok := totalByOwner[participation.OwnerAddress]; ok {
-				total.ActiveDays += participation.ActiveDays
-				total.Reward += participation.Reward
-			} else {
-				cpy := *participation
-				totalByOwner[participation.OwnerAddress] = &cpy
-			}
 		}
 
 		// Export rewards.
@@ -151,13 +146,7 @@ func (c *CalcCmd) Run(logger *zap.Logger, globals *Globals) error {
 		}
 
 		// Export cumulative rewards.
-		totalRewards := map[string]*big.Int{}
-		for _, participation := range totalByOwner {
-			totalRewards[participation.OwnerAddress], _ = new(big.Float).Mul(
-				big.NewFloat(participation.Reward),
-				big.NewFloat(math.Pow10(18)),
-			).Int(nil)
-		}
+		totalRewards := cumulativeRewards(totalByOwner)
 		f, err := os.Create(filepath.Join(dir, "cumulative.json"))
 		if err != nil {
 			return fmt.Errorf("failed to create cumulative.json: %w", err)
@@ -169,6 +158,16 @@ func (c *CalcCmd) Run(logger *zap.Logger, globals *Globals) error {
 			return fmt.Errorf("failed to encode total rewards: %w", err)
 		}
 
+		// Export the Merkle distributor artifact for this round's cumulative
+		// rewards, so owners who skip a round can still claim the sum later.
+		merkleOutput, err := buildMerkleOutput(merkle.Format(c.MerkleFormat), totalRewards, merkleOwnerIndex)
+		if err != nil {
+			return fmt.Errorf("failed to build merkle tree: %w", err)
+		}
+		if err := exportMerkle(dir, merkleOutput); err != nil {
+			return fmt.Errorf("failed to export merkle tree: %w", err)
+		}
+
 		logger.Info(
 			"Exported rewards for round",
 			zap.String("period", round.Period.String()),
@@ -187,20 +186,200 @@ func (c *CalcCmd) Run(logger *zap.Logger, globals *Globals) error {
 	if err := exportCSV(byOwner, filepath.Join(c.Dir, "by-owner.csv")); err != nil {
 		return fmt.Errorf("failed to export total owner rewards: %w", err)
 	}
-	if err := exportCSV(maps.Values(totalByValidator), filepath.Join(c.Dir, "total-by-validator.csv")); err != nil {
+	if err := exportCSV(sortedByKey(totalByValidator), filepath.Join(c.Dir, "total-by-validator.csv")); err != nil {
 		return fmt.Errorf("failed to export total validator rewards: %w", err)
 	}
-	if err := exportCSV(maps.Values(totalByOwner), filepath.Join(c.Dir, "total-by-owner.csv")); err != nil {
+	if err := exportCSV(sortedByKey(totalByOwner), filepath.Join(c.Dir, "total-by-owner.csv")); err != nil {
 		return fmt.Errorf("failed to export total owner rewards: %w", err)
 	}
 
 	return nil
 }
 
+// applyOwnerRemaps reattributes each validator's ActiveDays according to
+// remapper, splitting a participation in two when its owner transfer took
+// effect partway through period. It also warns when a remap's FromOwner
+// disagrees with the owner Postgres reports for that validator, since that
+// usually means the remap's effective_period is wrong.
+func applyOwnerRemaps(
+	logger *zap.Logger,
+	remaps rewards.OwnerRemaps,
+	remapper *remap.Remap,
+	period rewards.Period,
+	participations []*ValidatorParticipation,
+) []*ValidatorParticipation {
+	result := make([]*ValidatorParticipation, 0, len(participations))
+	for _, participation := range participations {
+		onChainOwner := common.HexToAddress(participation.OwnerAddress)
+
+		for _, r := range remaps {
+			if r.PublicKey == participation.PublicKey && r.EffectivePeriod == period &&
+				!strings.EqualFold(r.FromOwner, participation.OwnerAddress) {
+				logger.Warn("Owner remap's from_owner disagrees with the on-chain owner",
+					zap.String("public_key", r.PublicKey),
+					zap.String("from_owner", r.FromOwner),
+					zap.String("on_chain_owner", participation.OwnerAddress),
+				)
+			}
+		}
+
+		splits := remapper.Apply(participation.PublicKey, period, onChainOwner, participation.ActiveDays)
+		for _, split := range splits {
+			cpy := *participation
+			cpy.OwnerAddress = split.OwnerAddress.Hex()
+			cpy.ActiveDays = split.ActiveDays
+			result = append(result, &cpy)
+		}
+	}
+	return result
+}
+
+// aggregateOwnerParticipations re-derives owner-level rows from
+// validator-level ones, used in place of the active_days_by_owner SQL
+// function when owner remaps make the DB's own aggregation stale.
+func aggregateOwnerParticipations(validatorParticipations []*ValidatorParticipation) []*OwnerParticipation {
+	byOwner := map[string]*OwnerParticipation{}
+	var order []string
+	for _, participation := range validatorParticipations {
+		owner, ok := byOwner[participation.OwnerAddress]
+		if !ok {
+			owner = &OwnerParticipation{OwnerAddress: participation.OwnerAddress}
+			byOwner[participation.OwnerAddress] = owner
+			order = append(order, participation.OwnerAddress)
+		}
+		owner.Validators++
+		owner.ActiveDays += participation.ActiveDays
+	}
+	result := make([]*OwnerParticipation, len(order))
+	for i, address := range order {
+		result[i] = byOwner[address]
+	}
+	return result
+}
+
+// uniqueValidators returns the distinct public keys in participations. A
+// mid-round owner remap can split one validator's participation into two
+// rows (one per owner), so len(participations) overcounts the actual
+// validator set used for tier/reward lookups.
+func uniqueValidators(participations []*ValidatorParticipation) []string {
+	seen := map[string]struct{}{}
+	var keys []string
+	for _, participation := range participations {
+		if _, ok := seen[participation.PublicKey]; !ok {
+			seen[participation.PublicKey] = struct{}{}
+			keys = append(keys, participation.PublicKey)
+		}
+	}
+	return keys
+}
+
+// sortedByKey returns m's values ordered by their keys, so CSV output built
+// from a map is reproducible across runs despite Go's randomized map
+// iteration order.
+func sortedByKey[T any](m map[string]T) []T {
+	keys := maps.Keys(m)
+	sort.Strings(keys)
+	values := make([]T, len(keys))
+	for i, key := range keys {
+		values[i] = m[key]
+	}
+	return values
+}
+
+// calculateRound determines the tier and rewards for a round, attaches the
+// reward to each participation, and folds the round into the running
+// totalByValidator/totalByOwner maps. It has no dependency on Postgres, so
+// it's shared between CalcCmd.Run and the conformance runner.
+//
+// If round was partially halted, ActiveDays is capped at HaltedAtDay and
+// HaltedDays records how many days of the period were frozen, so downstream
+// consumers can distinguish "validator was offline" from "network was
+// halted".
+func calculateRound(
+	plan *rewards.Plan,
+	round rewards.Round,
+	validatorParticipations []*ValidatorParticipation,
+	ownerParticipations []*OwnerParticipation,
+	totalByValidator map[string]*ValidatorParticipation,
+	totalByOwner map[string]*OwnerParticipation,
+) (tier *rewards.Tier, daily, monthly, annual float64, err error) {
+	var haltedDays int
+	if round.HaltedAtDay > 0 {
+		haltedDays = round.Period.Days() - round.HaltedAtDay
+		for _, participation := range validatorParticipations {
+			if participation.ActiveDays > round.HaltedAtDay {
+				participation.ActiveDays = round.HaltedAtDay
+			}
+			participation.HaltedDays = haltedDays
+		}
+		// Re-derive each owner's cap from the (already capped) validator-level
+		// days rather than re-applying HaltedAtDay to the aggregate: an owner
+		// with several validators can have each of them individually within
+		// the cap while their sum exceeds it, and capping the sum directly
+		// would then disagree with the per-validator total below.
+		cappedOwnerActiveDays := map[string]int{}
+		for _, participation := range validatorParticipations {
+			cappedOwnerActiveDays[participation.OwnerAddress] += participation.ActiveDays
+		}
+		for _, participation := range ownerParticipations {
+			participation.ActiveDays = cappedOwnerActiveDays[participation.OwnerAddress]
+			participation.HaltedDays = haltedDays
+		}
+	}
+
+	tier, err = plan.Tier(len(uniqueValidators(validatorParticipations)))
+	if err != nil {
+		err = fmt.Errorf("failed to get tier: %w", err)
+		return
+	}
+	daily, monthly, annual, err = plan.ValidatorRewards(round.Period, len(uniqueValidators(validatorParticipations)))
+	if err != nil {
+		err = fmt.Errorf("failed to get reward: %w", err)
+		return
+	}
+
+	ownerActiveDays := map[string]int{}
+	for _, participation := range validatorParticipations {
+		participation.Reward = daily * float64(participation.ActiveDays)
+		ownerActiveDays[participation.OwnerAddress] += participation.ActiveDays
+
+		if total, ok := totalByValidator[participation.PublicKey]; ok {
+			// OwnerAddress always takes the latest value, so a remap's
+			// owner is reflected even after it's taken effect.
+			total.OwnerAddress = participation.OwnerAddress
+			total.ActiveDays += participation.ActiveDays
+			total.HaltedDays += participation.HaltedDays
+			total.Reward += participation.Reward
+		} else {
+			cpy := *participation
+			totalByValidator[participation.PublicKey] = &cpy
+		}
+	}
+	for _, participation := range ownerParticipations {
+		participation.Reward = daily * float64(participation.ActiveDays)
+
+		if participation.ActiveDays != ownerActiveDays[participation.OwnerAddress] {
+			err = fmt.Errorf("inconsistent active days for owner %q", participation.OwnerAddress)
+			return
+		}
+
+		if total, ok := totalByOwner[participation.OwnerAddress]; ok {
+			total.ActiveDays += participation.ActiveDays
+			total.HaltedDays += participation.HaltedDays
+			total.Reward += participation.Reward
+		} else {
+			cpy := *participation
+			totalByOwner[participation.OwnerAddress] = &cpy
+		}
+	}
+	return
+}
+
 type ValidatorParticipation struct {
 	OwnerAddress string
 	PublicKey    string
 	ActiveDays   int
+	HaltedDays   int     `boil:"-"`
 	Reward       float64 `boil:"-"`
 }
 
@@ -213,17 +392,30 @@ func (c *CalcCmd) validatorParticipations(
 	ctx context.Context,
 	period rewards.Period,
 ) ([]*ValidatorParticipation, error) {
-	var rewards []*ValidatorParticipation
-	return rewards, queries.Raw(
+	return queryValidatorParticipations(ctx, c.db, c.PerformanceProvider, c.MinimumDailyAttestations, period)
+}
+
+// queryValidatorParticipations is shared by CalcCmd and ExporterCmd, which
+// both need to turn active_days_by_validator rows into rewards per round.
+func queryValidatorParticipations(
+	ctx context.Context,
+	db *sql.DB,
+	performanceProvider string,
+	minimumDailyAttestations int,
+	period rewards.Period,
+) ([]*ValidatorParticipation, error) {
+	var participations []*ValidatorParticipation
+	return participations, queries.Raw(
 		"SELECT * FROM active_days_by_validator($1, $2, $3)",
-		c.PerformanceProvider, c.MinimumDailyAttestations, time.Time(period),
-	).Bind(ctx, c.db, &rewards)
+		performanceProvider, minimumDailyAttestations, time.Time(period),
+	).Bind(ctx, db, &participations)
 }
 
 type OwnerParticipation struct {
 	OwnerAddress string
 	Validators   int
 	ActiveDays   int
+	HaltedDays   int     `boil:"-"`
 	Reward       float64 `boil:"-"`
 }
 
@@ -236,11 +428,169 @@ func (c *CalcCmd) ownerParticipations(
 	ctx context.Context,
 	period rewards.Period,
 ) ([]*OwnerParticipation, error) {
-	var rewards []*OwnerParticipation
-	return rewards, queries.Raw(
+	return queryOwnerParticipations(ctx, c.db, c.PerformanceProvider, c.MinimumDailyAttestations, period)
+}
+
+// queryOwnerParticipations is shared by CalcCmd and ExporterCmd, which both
+// need to turn active_days_by_owner rows into rewards per round.
+func queryOwnerParticipations(
+	ctx context.Context,
+	db *sql.DB,
+	performanceProvider string,
+	minimumDailyAttestations int,
+	period rewards.Period,
+) ([]*OwnerParticipation, error) {
+	var participations []*OwnerParticipation
+	return participations, queries.Raw(
 		"SELECT * FROM active_days_by_owner($1, $2, $3)",
-		c.PerformanceProvider, c.MinimumDailyAttestations, time.Time(period),
-	).Bind(ctx, c.db, &rewards)
+		performanceProvider, minimumDailyAttestations, time.Time(period),
+	).Bind(ctx, db, &participations)
+}
+
+// merkleOutput is the shape of merkle.json: the round's root plus every
+// owner's leaf data and proof, so a claim contract or UI doesn't need to
+// recompute the tree.
+type merkleOutput struct {
+	Format string             `json:"format"`
+	Root   string             `json:"root"`
+	Owners []merkleOwnerEntry `json:"owners"`
+}
+
+type merkleOwnerEntry struct {
+	Index            int      `json:"index"`
+	OwnerAddress     string   `json:"ownerAddress"`
+	CumulativeAmount string   `json:"cumulativeAmount"`
+	Proof            []string `json:"proof"`
+}
+
+// ownerIndex assigns each owner a stable Merkle leaf index the first time
+// it sees their address, so re-deriving merkle.json for a later round never
+// reshuffles an earlier owner's index (and therefore their already-issued
+// proof) just because a new owner happens to sort before them. A round's
+// totalRewards only ever gains owners, never drops one, so assigning by
+// first-seen order keeps indices contiguous from 0 as required by
+// merkle.BuildTree.
+type ownerIndex struct {
+	byAddress map[string]int
+	order     []string
+}
+
+func newOwnerIndex() *ownerIndex {
+	return &ownerIndex{byAddress: map[string]int{}}
+}
+
+// assign returns totalRewards' addresses ordered by their leaf index,
+// issuing indices to any address seen for the first time. New addresses are
+// assigned in sorted order so ties within a round are reproducible.
+func (idx *ownerIndex) assign(totalRewards map[string]*big.Int) []string {
+	var newAddresses []string
+	for address := range totalRewards {
+		if _, seen := idx.byAddress[address]; !seen {
+			newAddresses = append(newAddresses, address)
+		}
+	}
+	sort.Strings(newAddresses)
+	for _, address := range newAddresses {
+		idx.byAddress[address] = len(idx.order)
+		idx.order = append(idx.order, address)
+	}
+
+	addresses := make([]string, 0, len(totalRewards))
+	for _, address := range idx.order {
+		if _, ok := totalRewards[address]; ok {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+// cumulativeRewards converts totalByOwner's running rewards (in SSV) into
+// wei-denominated amounts keyed by owner address, the shape cumulative.json
+// and the Merkle distributor both need.
+func cumulativeRewards(totalByOwner map[string]*OwnerParticipation) map[string]*big.Int {
+	totalRewards := map[string]*big.Int{}
+	for _, participation := range totalByOwner {
+		totalRewards[participation.OwnerAddress], _ = new(big.Float).Mul(
+			big.NewFloat(participation.Reward),
+			big.NewFloat(math.Pow10(18)),
+		).Int(nil)
+	}
+	return totalRewards
+}
+
+// buildMerkleOutput builds a Merkle distributor tree over totalRewards (the
+// cumulative amount owed to each owner as of this round), returning the
+// merkle.json contents alongside the per-owner proof it embeds.
+//
+// Owner leaves are ordered by index, which idx assigns on each owner's
+// first appearance, so the tree stays reproducible across rounds and an
+// owner's leaf (and proof) never changes once issued. It's shared between
+// CalcCmd.Run and the conformance runner, like calculateRound.
+func buildMerkleOutput(format merkle.Format, totalRewards map[string]*big.Int, idx *ownerIndex) (merkleOutput, error) {
+	addresses := idx.assign(totalRewards)
+
+	entries := make([]merkle.Entry, len(addresses))
+	for i, address := range addresses {
+		entries[i] = merkle.Entry{
+			Index:            i,
+			OwnerAddress:     common.HexToAddress(address),
+			CumulativeAmount: totalRewards[address],
+		}
+	}
+
+	tree, err := merkle.BuildTree(entries, format)
+	if err != nil {
+		return merkleOutput{}, fmt.Errorf("failed to build merkle tree: %w", err)
+	}
+
+	output := merkleOutput{
+		Format: string(format),
+		Root:   hexutil.Encode(tree.Root()),
+		Owners: make([]merkleOwnerEntry, len(entries)),
+	}
+	for i, entry := range entries {
+		proof, err := tree.Proof(entry.Index)
+		if err != nil {
+			return merkleOutput{}, fmt.Errorf("failed to get proof for %s: %w", entry.OwnerAddress, err)
+		}
+		hexProof := make([]string, len(proof))
+		for j, node := range proof {
+			hexProof[j] = hexutil.Encode(node)
+		}
+		output.Owners[i] = merkleOwnerEntry{
+			Index:            entry.Index,
+			OwnerAddress:     entry.OwnerAddress.Hex(),
+			CumulativeAmount: entry.CumulativeAmount.String(),
+			Proof:            hexProof,
+		}
+	}
+	return output, nil
+}
+
+// exportMerkle writes output and one proofs/<address>.json per owner into
+// dir.
+func exportMerkle(dir string, output merkleOutput) error {
+	proofsDir := filepath.Join(dir, "proofs")
+	if err := os.MkdirAll(proofsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %q: %w", proofsDir, err)
+	}
+	for _, owner := range output.Owners {
+		if err := exportJSON(owner, filepath.Join(proofsDir, owner.OwnerAddress+".json")); err != nil {
+			return fmt.Errorf("failed to export proof for %s: %w", owner.OwnerAddress, err)
+		}
+	}
+	return exportJSON(output, filepath.Join(dir, "merkle.json"))
+}
+
+func exportJSON(data any, fileName string) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("failed to create %q: %w", fileName, err)
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
 }
 
 func exportCSV(data any, fileName string) error {
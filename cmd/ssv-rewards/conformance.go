@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/bloxapp/ssv-rewards/pkg/rewards"
+	"github.com/bloxapp/ssv-rewards/pkg/rewards/merkle"
+	"github.com/bloxapp/ssv-rewards/pkg/rewards/remap"
+	"github.com/bloxapp/ssv-rewards/pkg/rewards/testvectors"
+	"github.com/gocarina/gocsv"
+	"go.uber.org/zap"
+)
+
+type ConformanceCmd struct {
+	VectorsDir    string `default:"./pkg/rewards/testvectors" help:"Directory containing the test-vector corpus."`
+	VectorsBranch string `help:"Git branch to clone an external vectors corpus from, instead of --vectors-dir."`
+	Record        bool   `help:"Regenerate expected outputs instead of comparing against them."`
+	Report        string `help:"Path to write a JUnit XML report to."`
+}
+
+func (c *ConformanceCmd) Run(logger *zap.Logger) error {
+	dir := c.VectorsDir
+	if c.VectorsBranch != "" {
+		checkout, err := cloneVectorsBranch(c.VectorsBranch)
+		if err != nil {
+			return fmt.Errorf("failed to clone vectors branch %q: %w", c.VectorsBranch, err)
+		}
+		defer os.RemoveAll(checkout)
+		dir = checkout
+	}
+
+	vectorDirs, err := testvectors.Discover(dir)
+	if err != nil {
+		return err
+	}
+
+	suite := junitTestSuite{Name: "rewards-conformance", Tests: len(vectorDirs)}
+	var failures int
+	for _, vectorDir := range vectorDirs {
+		name := filepath.Base(vectorDir)
+		if err := runVector(vectorDir, c.Record); err != nil {
+			failures++
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:    name,
+				Failure: &junitFailure{Message: err.Error()},
+			})
+			logger.Error("Vector failed", zap.String("vector", name), zap.Error(err))
+		} else {
+			suite.Cases = append(suite.Cases, junitTestCase{Name: name})
+			logger.Info("Vector passed", zap.String("vector", name))
+		}
+	}
+	suite.Failures = failures
+
+	if c.Report != "" {
+		if err := writeJUnitReport(c.Report, suite); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+	if failures > 0 {
+		return fmt.Errorf("%d/%d vectors failed", failures, len(vectorDirs))
+	}
+	return nil
+}
+
+// cloneVectorsBranch shallow-clones branch of the community-maintained
+// vectors corpus into a temporary directory, so --vectors-branch doesn't
+// require a local checkout.
+func cloneVectorsBranch(branch string) (string, error) {
+	dir, err := os.MkdirTemp("", "ssv-rewards-vectors-")
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.Command("git", "clone", "--depth", "1", "--branch", branch,
+		"https://github.com/bloxapp/ssv-rewards-vectors", dir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("%w: %s", err, out)
+	}
+	return dir, nil
+}
+
+// runVector runs the calculator over a single vector's fixtures and either
+// records its expected artifacts or asserts they match byte-for-byte,
+// ignoring trailing newlines.
+func runVector(dir string, record bool) error {
+	vector, err := testvectors.Load(dir)
+	if err != nil {
+		return fmt.Errorf("failed to load vector: %w", err)
+	}
+
+	plan, err := rewards.ParsePlan(vector.Plan)
+	if err != nil {
+		return fmt.Errorf("failed to parse rewards.yaml: %w", err)
+	}
+	if len(vector.Rounds) != len(plan.Rounds) {
+		return fmt.Errorf("vector has %d round fixtures but plan has %d rounds", len(vector.Rounds), len(plan.Rounds))
+	}
+
+	remapper := remap.New(plan.OwnerRemaps)
+	var byValidator []*ValidatorParticipation
+	var byOwner []*OwnerParticipation
+	totalByValidator := map[string]*ValidatorParticipation{}
+	totalByOwner := map[string]*OwnerParticipation{}
+	merkleOwnerIndex := newOwnerIndex()
+	artifacts := map[string][]byte{}
+
+	for i, round := range plan.Rounds {
+		if halted, _ := plan.Halted(round.Period); halted {
+			continue // Mirrors CalcCmd.Run: fully halted rounds don't contribute at all.
+		}
+		fixture := vector.Rounds[i]
+
+		validatorParticipations := make([]*ValidatorParticipation, len(fixture.Validators))
+		for j, v := range fixture.Validators {
+			validatorParticipations[j] = &ValidatorParticipation{
+				OwnerAddress: v.OwnerAddress,
+				PublicKey:    v.PublicKey,
+				ActiveDays:   v.ActiveDays,
+			}
+		}
+		validatorParticipations = applyOwnerRemaps(zap.NewNop(), plan.OwnerRemaps, remapper, round.Period, validatorParticipations)
+
+		var ownerParticipations []*OwnerParticipation
+		if len(plan.OwnerRemaps) > 0 {
+			ownerParticipations = aggregateOwnerParticipations(validatorParticipations)
+		} else {
+			ownerParticipations = make([]*OwnerParticipation, len(fixture.Owners))
+			for j, o := range fixture.Owners {
+				ownerParticipations[j] = &OwnerParticipation{
+					OwnerAddress: o.OwnerAddress,
+					Validators:   o.Validators,
+					ActiveDays:   o.ActiveDays,
+				}
+			}
+		}
+
+		if _, _, _, _, err := calculateRound(
+			plan, round, validatorParticipations, ownerParticipations, totalByValidator, totalByOwner,
+		); err != nil {
+			if vector.Meta.ExpectError {
+				return nil
+			}
+			return fmt.Errorf("round %d: %w", i, err)
+		}
+
+		byValidator = append(byValidator, validatorParticipations...)
+		byOwner = append(byOwner, ownerParticipations...)
+
+		// Build and record this round's merkle.json from the running
+		// cumulative totals, rather than only the corpus's final snapshot,
+		// so a round that reshuffles an earlier owner's leaf index (e.g.
+		// because a new owner now sorts before them) fails in the round
+		// it's introduced.
+		roundTotalRewards := cumulativeRewards(totalByOwner)
+		if len(roundTotalRewards) > 0 {
+			merkleOutput, err := buildMerkleOutput(merkle.FormatSortedPair, roundTotalRewards, merkleOwnerIndex)
+			if err != nil {
+				return fmt.Errorf("round %d: failed to build merkle.json: %w", i, err)
+			}
+			merkleJSON, err := marshalIndentJSON(merkleOutput)
+			if err != nil {
+				return fmt.Errorf("round %d: failed to marshal merkle.json: %w", i, err)
+			}
+			artifacts[filepath.Join("rounds", round.Period.String(), "merkle.json")] = merkleJSON
+			artifacts["merkle.json"] = merkleJSON // Final round's snapshot also doubles as the corpus-level artifact.
+		}
+	}
+	if vector.Meta.ExpectError {
+		return fmt.Errorf("vector expected calculation to fail, but it succeeded")
+	}
+
+	totalRewards := cumulativeRewards(totalByOwner)
+
+	validatorCSV, err := gocsv.MarshalBytes(byValidator)
+	if err != nil {
+		return fmt.Errorf("failed to marshal by-validator.csv: %w", err)
+	}
+	ownerCSV, err := gocsv.MarshalBytes(byOwner)
+	if err != nil {
+		return fmt.Errorf("failed to marshal by-owner.csv: %w", err)
+	}
+	totalValidatorCSV, err := gocsv.MarshalBytes(sortedByKey(totalByValidator))
+	if err != nil {
+		return fmt.Errorf("failed to marshal total-by-validator.csv: %w", err)
+	}
+	totalOwnerCSV, err := gocsv.MarshalBytes(sortedByKey(totalByOwner))
+	if err != nil {
+		return fmt.Errorf("failed to marshal total-by-owner.csv: %w", err)
+	}
+	cumulativeJSON, err := marshalIndentJSON(totalRewards)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cumulative.json: %w", err)
+	}
+
+	artifacts["by-validator.csv"] = validatorCSV
+	artifacts["by-owner.csv"] = ownerCSV
+	artifacts["total-by-validator.csv"] = totalValidatorCSV
+	artifacts["total-by-owner.csv"] = totalOwnerCSV
+	artifacts["cumulative.json"] = cumulativeJSON
+
+	if record {
+		expectedDir := filepath.Join(dir, "expected")
+		if err := os.MkdirAll(expectedDir, 0755); err != nil {
+			return fmt.Errorf("failed to create expected directory: %w", err)
+		}
+		for name, data := range artifacts {
+			path := filepath.Join(expectedDir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return fmt.Errorf("failed to create directory for %s: %w", name, err)
+			}
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				return fmt.Errorf("failed to record %s: %w", name, err)
+			}
+		}
+		return nil
+	}
+
+	for name, actual := range artifacts {
+		expected, err := os.ReadFile(vector.ExpectedPath(name))
+		if os.IsNotExist(err) {
+			continue // Vector doesn't assert on this artifact.
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read expected %s: %w", name, err)
+		}
+		if !bytes.Equal(bytes.TrimRight(expected, "\n"), bytes.TrimRight(actual, "\n")) {
+			return fmt.Errorf("%s does not match expected output", name)
+		}
+	}
+	return nil
+}
+
+func marshalIndentJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type junitTestSuite struct {
+	XMLName  xml.Name        `xml:"testsuite"`
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name    string        `xml:"name,attr"`
+	Failure *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+func writeJUnitReport(path string, suite junitTestSuite) error {
+	data, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append([]byte(xml.Header), data...), 0644)
+}
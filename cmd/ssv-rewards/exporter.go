@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/bloxapp/ssv-rewards/pkg/models"
+	"github.com/bloxapp/ssv-rewards/pkg/rewards"
+	"github.com/bloxapp/ssv-rewards/pkg/rewards/metrics"
+	"github.com/bloxapp/ssv-rewards/pkg/rewards/remap"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+type ExporterCmd struct {
+	ListenAddr               string        `default:":9090"    help:"Address to serve /metrics and /healthz on."`
+	Interval                 time.Duration `default:"10m"      help:"How often to recalculate rewards."`
+	PerformanceProvider      string        `default:"beaconcha" help:"Performance provider to use."                                       enum:"beaconcha,e2m"`
+	MinimumDailyAttestations int           `default:"202"      help:"Minimum attestations in a day to be considered active."`
+	StaleAfter               time.Duration `default:"2h"       help:"How stale LatestValidatorPerformance may be before /healthz fails."`
+	CumulativeMetrics        bool          `help:"Expose the high-cardinality ssv_rewards_cumulative_ssv metric."`
+
+	db *sql.DB
+}
+
+func (c *ExporterCmd) Run(logger *zap.Logger, globals *Globals) error {
+	ctx := context.Background()
+
+	var err error
+	c.db, err = sql.Open("postgres", globals.Postgres)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	logger.Info("Connected to PostgreSQL")
+
+	registry := prometheus.NewRegistry()
+	m := metrics.New(registry, c.CumulativeMetrics)
+	health := &exporterHealth{staleAfter: c.StaleAfter}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/healthz", health.handle)
+
+	server := &http.Server{Addr: c.ListenAddr, Handler: mux}
+	go func() {
+		logger.Info("Serving metrics", zap.String("addr", c.ListenAddr))
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("Metrics server stopped", zap.Error(err))
+		}
+	}()
+
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+	for {
+		start := time.Now()
+		if err := c.calculate(ctx, logger, m, health); err != nil {
+			logger.Error("Calculation failed", zap.Error(err))
+		} else {
+			logger.Info("Updated metrics", zap.Duration("duration", time.Since(start)))
+		}
+		<-ticker.C
+	}
+}
+
+// calculate mirrors CalcCmd.Run's aggregation, without writing any files, to
+// keep the exporter's view of the plan always up to date.
+func (c *ExporterCmd) calculate(ctx context.Context, logger *zap.Logger, m *metrics.Metrics, health *exporterHealth) error {
+	start := time.Now()
+
+	data, err := os.ReadFile("rewards.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to read rewards.yaml: %w", err)
+	}
+	plan, err := rewards.ParsePlan(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse rewards plan: %w", err)
+	}
+
+	state, err := models.States().One(ctx, c.db)
+	if err != nil {
+		return fmt.Errorf("failed to get state: %w", err)
+	}
+
+	currentPeriod := rewards.PeriodAt(time.Now())
+	var currentPlanDataMissing = true
+	for _, round := range plan.Rounds {
+		if round.Period == currentPeriod {
+			currentPlanDataMissing = round.ETHAPR <= 0 || round.SSVETH <= 0
+			break
+		}
+	}
+
+	if state.EarliestValidatorPerformance.IsZero() || state.LatestValidatorPerformance.IsZero() {
+		health.update(time.Time{}, currentPlanDataMissing)
+		return fmt.Errorf("validator performance data is not available")
+	}
+	health.update(state.LatestValidatorPerformance.Time, currentPlanDataMissing)
+	latestValidatorPerformancePeriod := rewards.PeriodAt(state.LatestValidatorPerformance.Time)
+
+	var completeRounds []rewards.Round
+	for _, round := range plan.Rounds {
+		if round.ETHAPR > 0 && round.SSVETH > 0 &&
+			round.Period.LastDay().Before(latestValidatorPerformancePeriod.FirstDay()) {
+			if halted, _ := plan.Halted(round.Period); halted {
+				continue
+			}
+			completeRounds = append(completeRounds, round)
+		}
+	}
+
+	remapper := remap.New(plan.OwnerRemaps)
+	totalByValidator := map[string]*ValidatorParticipation{}
+	totalByOwner := map[string]*OwnerParticipation{}
+	var summaries []metrics.RoundSummary
+	for _, round := range completeRounds {
+		validatorParticipations, err := queryValidatorParticipations(
+			ctx, c.db, c.PerformanceProvider, c.MinimumDailyAttestations, round.Period,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to get validator participations: %w", err)
+		}
+		validatorParticipations = applyOwnerRemaps(logger, plan.OwnerRemaps, remapper, round.Period, validatorParticipations)
+
+		var ownerParticipations []*OwnerParticipation
+		if len(plan.OwnerRemaps) > 0 {
+			ownerParticipations = aggregateOwnerParticipations(validatorParticipations)
+		} else {
+			ownerParticipations, err = queryOwnerParticipations(
+				ctx, c.db, c.PerformanceProvider, c.MinimumDailyAttestations, round.Period,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to get owner participations: %w", err)
+			}
+		}
+
+		tier, dailyReward, _, _, err := calculateRound(
+			plan, round, validatorParticipations, ownerParticipations, totalByValidator, totalByOwner,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to calculate round %s: %w", round.Period, err)
+		}
+
+		summaries = append(summaries, metrics.RoundSummary{
+			Period:           round.Period.String(),
+			Tier:             tier.MaxParticipants,
+			ActiveValidators: len(uniqueValidators(validatorParticipations)),
+			ActiveOwners:     len(ownerParticipations),
+			DailyRewardSSV:   dailyReward,
+			AnnualAPR:        round.ETHAPR * tier.APRBoost,
+			PlanETHAPR:       round.ETHAPR,
+			PlanSSVETH:       round.SSVETH,
+		})
+	}
+
+	cumulative := make(map[string]float64, len(totalByOwner))
+	for _, participation := range totalByOwner {
+		cumulative[participation.OwnerAddress] = participation.Reward
+	}
+
+	m.Observe(summaries, cumulative, time.Since(start))
+	return nil
+}
+
+// exporterHealth backs /healthz: it fails when validator performance data
+// is stale, or when the current period's ETHAPR/SSVETH are unset.
+type exporterHealth struct {
+	staleAfter time.Duration
+
+	mu              sync.Mutex
+	lastPerformance time.Time
+	planDataMissing bool
+}
+
+func (h *exporterHealth) update(lastPerformance time.Time, planDataMissing bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastPerformance = lastPerformance
+	h.planDataMissing = planDataMissing
+}
+
+func (h *exporterHealth) handle(w http.ResponseWriter, _ *http.Request) {
+	h.mu.Lock()
+	lastPerformance, planDataMissing := h.lastPerformance, h.planDataMissing
+	h.mu.Unlock()
+
+	if planDataMissing {
+		http.Error(w, "current period's eth_apr/ssv_eth are unset in rewards.yaml", http.StatusServiceUnavailable)
+		return
+	}
+	if lastPerformance.IsZero() || time.Since(lastPerformance) > h.staleAfter {
+		http.Error(w, fmt.Sprintf("validator performance data is stale (last update: %s)", lastPerformance), http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
@@ -0,0 +1,163 @@
+// Package merkle builds Merkle trees of cumulative owner rewards for
+// on-chain claim contracts, in the style of Uniswap's MerkleDistributor.
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Format selects how sibling hashes are combined when building intermediate
+// tree nodes.
+type Format string
+
+const (
+	// FormatSortedPair sorts each pair of child hashes before hashing them
+	// together, so the tree (and therefore the root) doesn't depend on
+	// left/right order. This is what most modern MerkleDistributor
+	// contracts expect.
+	FormatSortedPair Format = "sorted-pair"
+
+	// FormatOpenZeppelin matches OpenZeppelin's MerkleProof library, whose
+	// _hashPair also sorts each pair before hashing — so it produces the
+	// same tree as FormatSortedPair. It's kept as its own flag value so an
+	// operator targeting an OZ-based claim contract can say so explicitly.
+	FormatOpenZeppelin Format = "openzeppelin"
+)
+
+// Entry is a single Merkle leaf: an owner's cumulative reward as of a round.
+type Entry struct {
+	Index            int
+	OwnerAddress     common.Address
+	CumulativeAmount *big.Int
+}
+
+// Tree is a binary Merkle tree of Entry leaves, built so a Solidity
+// MerkleDistributor-style contract can verify a (index, owner, amount)
+// leaf against Root() using a Proof().
+//
+// Odd nodes out are duplicated and hashed with themselves, a common
+// convention for unbalanced trees.
+type Tree struct {
+	format  Format
+	entries []Entry
+	layers  [][][]byte // layers[0] are leaf hashes, layers[len-1] is [root].
+}
+
+var leafArguments = abi.Arguments{
+	{Type: mustABIType("uint256")},
+	{Type: mustABIType("address")},
+	{Type: mustABIType("uint256")},
+}
+
+func mustABIType(name string) abi.Type {
+	typ, err := abi.NewType(name, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
+
+// BuildTree builds a Merkle tree from entries. Entries must have contiguous
+// indices starting at 0; they're sorted by index before hashing so the
+// resulting tree doesn't depend on input order.
+func BuildTree(entries []Entry, format Format) (*Tree, error) {
+	if len(entries) == 0 {
+		return nil, errors.New("no entries")
+	}
+	switch format {
+	case FormatSortedPair, FormatOpenZeppelin:
+	default:
+		return nil, fmt.Errorf("unknown merkle format: %q", format)
+	}
+
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Index < sorted[j].Index })
+	for i, entry := range sorted {
+		if entry.Index != i {
+			return nil, fmt.Errorf("entries must have contiguous indices starting at 0, got gap at %d", i)
+		}
+	}
+
+	leaves := make([][]byte, len(sorted))
+	for i, entry := range sorted {
+		leaf, err := hashLeaf(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash leaf %d: %w", entry.Index, err)
+		}
+		leaves[i] = leaf
+	}
+
+	tree := &Tree{format: format, entries: sorted, layers: [][][]byte{leaves}}
+	for len(tree.layers[len(tree.layers)-1]) > 1 {
+		tree.layers = append(tree.layers, tree.nextLayer(tree.layers[len(tree.layers)-1]))
+	}
+	return tree, nil
+}
+
+// hashLeaf hashes an entry as keccak256(abi.encode(index, address, amount)),
+// matching the standard MerkleDistributor leaf encoding.
+func hashLeaf(entry Entry) ([]byte, error) {
+	packed, err := leafArguments.Pack(big.NewInt(int64(entry.Index)), entry.OwnerAddress, entry.CumulativeAmount)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(packed), nil
+}
+
+func (t *Tree) nextLayer(nodes [][]byte) [][]byte {
+	next := make([][]byte, 0, (len(nodes)+1)/2)
+	for i := 0; i < len(nodes); i += 2 {
+		left := nodes[i]
+		right := left
+		if i+1 < len(nodes) {
+			right = nodes[i+1]
+		}
+		next = append(next, t.hashPair(left, right))
+	}
+	return next
+}
+
+func (t *Tree) hashPair(a, b []byte) []byte {
+	// Both formats sort: FormatOpenZeppelin's _hashPair behaves identically
+	// to FormatSortedPair.
+	if bytes.Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	pair := make([]byte, 0, len(a)+len(b))
+	pair = append(pair, a...)
+	pair = append(pair, b...)
+	return crypto.Keccak256(pair)
+}
+
+// Root returns the Merkle root.
+func (t *Tree) Root() []byte {
+	return t.layers[len(t.layers)-1][0]
+}
+
+// Proof returns the sibling hashes needed to verify the leaf at index,
+// ordered from leaf to root.
+func (t *Tree) Proof(index int) ([][]byte, error) {
+	if index < 0 || index >= len(t.entries) {
+		return nil, fmt.Errorf("index %d out of range", index)
+	}
+	proof := make([][]byte, 0, len(t.layers)-1)
+	for _, layer := range t.layers[:len(t.layers)-1] {
+		siblingIndex := index ^ 1
+		if siblingIndex < len(layer) {
+			proof = append(proof, layer[siblingIndex])
+		} else {
+			proof = append(proof, layer[index])
+		}
+		index /= 2
+	}
+	return proof, nil
+}
@@ -0,0 +1,124 @@
+// Package metrics exposes the reward calculator's output as Prometheus
+// gauges, so operators can alert on drift or missing plan data before a
+// round closes instead of discovering it from the exported CSVs.
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RoundSummary is the subset of a calculated round's results that's worth
+// exposing as metrics.
+type RoundSummary struct {
+	Period           string
+	Tier             int
+	ActiveValidators int
+	ActiveOwners     int
+	DailyRewardSSV   float64
+	AnnualAPR        float64
+	PlanETHAPR       float64
+	PlanSSVETH       float64
+}
+
+// Metrics holds every gauge this package exports. CumulativeSSV is
+// registered only when cumulativeEnabled is set, since it's keyed by owner
+// address and can grow without bound.
+type Metrics struct {
+	ActiveValidators    *prometheus.GaugeVec
+	ActiveOwners        *prometheus.GaugeVec
+	DailyRewardSSV      *prometheus.GaugeVec
+	AnnualAPR           *prometheus.GaugeVec
+	CumulativeSSV       *prometheus.GaugeVec
+	PlanRoundETHAPR     *prometheus.GaugeVec
+	PlanRoundSSVETH     *prometheus.GaugeVec
+	LastCalculation     prometheus.Gauge
+	CalculationDuration prometheus.Gauge
+
+	cumulativeEnabled bool
+}
+
+// New creates and registers the exporter's gauges. cumulativeEnabled gates
+// the high-cardinality ssv_rewards_cumulative_ssv metric.
+func New(registerer prometheus.Registerer, cumulativeEnabled bool) *Metrics {
+	m := &Metrics{
+		ActiveValidators: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ssv_rewards_active_validators",
+			Help: "Number of validators active in a round, by tier.",
+		}, []string{"period", "tier"}),
+		ActiveOwners: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ssv_rewards_active_owners",
+			Help: "Number of owners active in a round.",
+		}, []string{"period"}),
+		DailyRewardSSV: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ssv_rewards_daily_reward_ssv",
+			Help: "Daily reward per active validator day, in SSV, for a round.",
+		}, []string{"period"}),
+		AnnualAPR: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ssv_rewards_annual_apr",
+			Help: "Annualized validator reward as a fraction of 32 ETH, by tier, for a round.",
+		}, []string{"period", "tier"}),
+		CumulativeSSV: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ssv_rewards_cumulative_ssv",
+			Help: "Cumulative reward owed to an owner across all rounds, in SSV.",
+		}, []string{"owner"}),
+		PlanRoundETHAPR: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ssv_rewards_plan_round_eth_apr",
+			Help: "ETHAPR configured in rewards.yaml for a round.",
+		}, []string{"period"}),
+		PlanRoundSSVETH: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ssv_rewards_plan_round_ssv_eth",
+			Help: "SSVETH configured in rewards.yaml for a round.",
+		}, []string{"period"}),
+		LastCalculation: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "ssv_rewards_last_calculation_timestamp",
+			Help: "Unix timestamp of the last successful calculation run.",
+		}),
+		CalculationDuration: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "ssv_rewards_calculation_duration_seconds",
+			Help: "Duration of the last calculation run, in seconds.",
+		}),
+		cumulativeEnabled: cumulativeEnabled,
+	}
+
+	registerer.MustRegister(
+		m.ActiveValidators,
+		m.ActiveOwners,
+		m.DailyRewardSSV,
+		m.AnnualAPR,
+		m.PlanRoundETHAPR,
+		m.PlanRoundSSVETH,
+		m.LastCalculation,
+		m.CalculationDuration,
+	)
+	if cumulativeEnabled {
+		registerer.MustRegister(m.CumulativeSSV)
+	}
+	return m
+}
+
+// Observe updates the gauges from a completed calculation run. cumulative
+// maps owner address to its cumulative reward in SSV; it's ignored unless
+// cumulativeEnabled was set in New.
+func (m *Metrics) Observe(rounds []RoundSummary, cumulative map[string]float64, duration time.Duration) {
+	for _, round := range rounds {
+		tier := strconv.Itoa(round.Tier)
+		m.ActiveValidators.WithLabelValues(round.Period, tier).Set(float64(round.ActiveValidators))
+		m.ActiveOwners.WithLabelValues(round.Period).Set(float64(round.ActiveOwners))
+		m.DailyRewardSSV.WithLabelValues(round.Period).Set(round.DailyRewardSSV)
+		m.AnnualAPR.WithLabelValues(round.Period, tier).Set(round.AnnualAPR)
+		m.PlanRoundETHAPR.WithLabelValues(round.Period).Set(round.PlanETHAPR)
+		m.PlanRoundSSVETH.WithLabelValues(round.Period).Set(round.PlanSSVETH)
+	}
+
+	if m.cumulativeEnabled {
+		for owner, reward := range cumulative {
+			m.CumulativeSSV.WithLabelValues(owner).Set(reward)
+		}
+	}
+
+	m.LastCalculation.Set(float64(time.Now().Unix()))
+	m.CalculationDuration.Set(duration.Seconds())
+}
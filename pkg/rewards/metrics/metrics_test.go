@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestMetricsObserve checks that Observe sets each gauge to the value its
+// RoundSummary carries, labeled by the right period/tier, rather than
+// silently dropping a field or mislabeling it.
+func TestMetricsObserve(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := New(registry, true)
+
+	m.Observe(
+		[]RoundSummary{{
+			Period:           "2023-01",
+			Tier:             10,
+			ActiveValidators: 3,
+			ActiveOwners:     2,
+			DailyRewardSSV:   1.5,
+			AnnualAPR:        0.093,
+			PlanETHAPR:       0.093,
+			PlanSSVETH:       0.008,
+		}},
+		map[string]float64{"0xaaaa": 46.5},
+		2*time.Second,
+	)
+
+	if got := testutil.ToFloat64(m.ActiveValidators.WithLabelValues("2023-01", "10")); got != 3 {
+		t.Errorf("ActiveValidators = %v, want 3", got)
+	}
+	if got := testutil.ToFloat64(m.ActiveOwners.WithLabelValues("2023-01")); got != 2 {
+		t.Errorf("ActiveOwners = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(m.DailyRewardSSV.WithLabelValues("2023-01")); got != 1.5 {
+		t.Errorf("DailyRewardSSV = %v, want 1.5", got)
+	}
+	if got := testutil.ToFloat64(m.AnnualAPR.WithLabelValues("2023-01", "10")); got != 0.093 {
+		t.Errorf("AnnualAPR = %v, want 0.093", got)
+	}
+	if got := testutil.ToFloat64(m.PlanRoundETHAPR.WithLabelValues("2023-01")); got != 0.093 {
+		t.Errorf("PlanRoundETHAPR = %v, want 0.093", got)
+	}
+	if got := testutil.ToFloat64(m.PlanRoundSSVETH.WithLabelValues("2023-01")); got != 0.008 {
+		t.Errorf("PlanRoundSSVETH = %v, want 0.008", got)
+	}
+	if got := testutil.ToFloat64(m.CumulativeSSV.WithLabelValues("0xaaaa")); got != 46.5 {
+		t.Errorf("CumulativeSSV = %v, want 46.5", got)
+	}
+	if got := testutil.ToFloat64(m.CalculationDuration); got != 2 {
+		t.Errorf("CalculationDuration = %v, want 2", got)
+	}
+}
+
+// TestMetricsNewCumulativeDisabled checks that the high-cardinality
+// CumulativeSSV gauge isn't registered when cumulativeEnabled is false, so
+// scraping it doesn't pay for or expose per-owner cardinality nobody asked
+// for.
+func TestMetricsNewCumulativeDisabled(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := New(registry, false)
+	// An empty GaugeVec gathers no family even when registered, so give it a
+	// series that would show up if it were.
+	m.CumulativeSSV.WithLabelValues("0xaaaa").Set(1)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() == "ssv_rewards_cumulative_ssv" {
+			t.Errorf("ssv_rewards_cumulative_ssv should not be registered when cumulativeEnabled is false")
+		}
+	}
+}
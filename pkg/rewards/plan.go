@@ -15,9 +15,11 @@ const (
 )
 
 type Plan struct {
-	Criteria Criteria `yaml:"criteria"`
-	Tiers    Tiers    `yaml:"tiers"`
-	Rounds   Rounds   `yaml:"rounds"`
+	Criteria    Criteria    `yaml:"criteria"`
+	Tiers       Tiers       `yaml:"tiers"`
+	Rounds      Rounds      `yaml:"rounds"`
+	Halts       Halts       `yaml:"halts"`
+	OwnerRemaps OwnerRemaps `yaml:"owner_remaps"`
 }
 
 // ParsePlan parses the given YAML document into a Plan.
@@ -61,9 +63,73 @@ func (r *Plan) validate() error {
 			return fmt.Errorf("duplicate round: %s", r.Rounds[i].Period)
 		}
 	}
+	for _, round := range r.Rounds {
+		if round.HaltedAtDay != 0 && (round.HaltedAtDay < 1 || round.HaltedAtDay > round.Period.Days()) {
+			return fmt.Errorf("round %s: halted_at_day must be between 1 and %d", round.Period, round.Period.Days())
+		}
+	}
+
+	// Halts.
+	if len(r.Halts) > 0 {
+		sorted := make(Halts, len(r.Halts))
+		copy(sorted, r.Halts)
+		sort.Slice(sorted, func(i, j int) bool {
+			return time.Time(sorted[i].FromPeriod).Before(time.Time(sorted[j].FromPeriod))
+		})
+		for i, halt := range sorted {
+			if time.Time(halt.ToPeriod).Before(time.Time(halt.FromPeriod)) {
+				return fmt.Errorf("halt %q: to_period is before from_period", halt.Reason)
+			}
+			if !r.hasRound(halt.FromPeriod) || !r.hasRound(halt.ToPeriod) {
+				return fmt.Errorf("halt %q references a period with no round", halt.Reason)
+			}
+			if i > 0 && !time.Time(sorted[i-1].ToPeriod).Before(time.Time(halt.FromPeriod)) {
+				return fmt.Errorf("overlapping halts: %q and %q", sorted[i-1].Reason, halt.Reason)
+			}
+		}
+	}
+
+	// Owner remaps.
+	seen := map[string]bool{}
+	for _, remap := range r.OwnerRemaps {
+		key := remap.PublicKey + "@" + remap.EffectivePeriod.String()
+		if seen[key] {
+			return fmt.Errorf("duplicate owner remap for %s at %s", remap.PublicKey, remap.EffectivePeriod)
+		}
+		seen[key] = true
+		if !r.hasRound(remap.EffectivePeriod) {
+			return fmt.Errorf("owner remap for %s references unknown period %s", remap.PublicKey, remap.EffectivePeriod)
+		}
+		if remap.EffectiveDay != 0 &&
+			(remap.EffectiveDay < 1 || remap.EffectiveDay > remap.EffectivePeriod.Days()) {
+			return fmt.Errorf("owner remap for %s: effective_day must be between 1 and %d",
+				remap.PublicKey, remap.EffectivePeriod.Days())
+		}
+	}
 	return nil
 }
 
+func (r *Plan) hasRound(period Period) bool {
+	for _, round := range r.Rounds {
+		if round.Period == period {
+			return true
+		}
+	}
+	return false
+}
+
+// Halted reports whether period falls fully within one of the plan's halt
+// ranges, in which case it should be skipped entirely rather than have its
+// rewards calculated.
+func (r *Plan) Halted(period Period) (bool, string) {
+	for _, halt := range r.Halts {
+		if !time.Time(period).Before(time.Time(halt.FromPeriod)) && !time.Time(period).After(time.Time(halt.ToPeriod)) {
+			return true, halt.Reason
+		}
+	}
+	return false, ""
+}
+
 func (r *Plan) ValidatorRewards(
 	period Period,
 	participants int,
@@ -109,6 +175,10 @@ type Round struct {
 	Period Period  `yaml:"period"`
 	ETHAPR float64 `yaml:"eth_apr"`
 	SSVETH float64 `yaml:"ssv_eth"`
+
+	// HaltedAtDay freezes reward accrual for a partial period: days after it
+	// don't count towards ActiveDays. Zero means the round wasn't halted.
+	HaltedAtDay int `yaml:"halted_at_day"`
 }
 
 type Rounds []Round
@@ -117,6 +187,36 @@ func (r Rounds) Len() int           { return len(r) }
 func (r Rounds) Less(i, j int) bool { return time.Time(r[i].Period).Before(time.Time(r[j].Period)) }
 func (r Rounds) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
 
+// Halt is a chain-halt-style record freezing reward accrual for every round
+// from FromPeriod to ToPeriod, inclusive.
+type Halt struct {
+	FromPeriod Period `yaml:"from_period"`
+	ToPeriod   Period `yaml:"to_period"`
+	Reason     string `yaml:"reason"`
+}
+
+type Halts []Halt
+
+// OwnerRemap records a validator ownership transfer, so rewards accrued
+// before and after the transfer are attributed to the correct owner.
+type OwnerRemap struct {
+	PublicKey       string `yaml:"public_key"`
+	FromOwner       string `yaml:"from_owner"`
+	ToOwner         string `yaml:"to_owner"`
+	EffectivePeriod Period `yaml:"effective_period"`
+
+	// EffectiveDay splits EffectivePeriod's round around the transfer: a
+	// share of the validator's ActiveDays proportional to the days before it
+	// stays with the validator's current owner (the on-chain owner, or an
+	// earlier remap's ToOwner), the rest goes to ToOwner. FromOwner is only
+	// compared with the on-chain owner, to log a warning. Zero means
+	// the transfer takes effect from the round's very first day, so the
+	// whole round goes to ToOwner.
+	EffectiveDay int `yaml:"effective_day"`
+}
+
+type OwnerRemaps []OwnerRemap
+
 type Tier struct {
 	MaxParticipants int     `yaml:"max_participants"`
 	APRBoost        float64 `yaml:"apr_boost"`
@@ -0,0 +1,89 @@
+// Package remap resolves validator ownership transfers that occur mid-plan,
+// so a validator's ActiveDays and Reward are attributed to the correct
+// owner on either side of the transfer.
+package remap
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bloxapp/ssv-rewards/pkg/rewards"
+)
+
+// Split is a validator's participation attributed to a single owner within
+// a round. A round that straddles a transfer produces two Splits.
+type Split struct {
+	OwnerAddress common.Address
+	ActiveDays   int
+}
+
+// Remap resolves a validator's owner across rewards.OwnerRemap transfers.
+type Remap struct {
+	byPublicKey map[string][]rewards.OwnerRemap
+}
+
+// New builds a Remap from the plan's owner_remaps.
+func New(remaps rewards.OwnerRemaps) *Remap {
+	byPublicKey := map[string][]rewards.OwnerRemap{}
+	for _, r := range remaps {
+		byPublicKey[r.PublicKey] = append(byPublicKey[r.PublicKey], r)
+	}
+	for _, entries := range byPublicKey {
+		sort.Slice(entries, func(i, j int) bool {
+			return time.Time(entries[i].EffectivePeriod).Before(time.Time(entries[j].EffectivePeriod))
+		})
+	}
+	return &Remap{byPublicKey: byPublicKey}
+}
+
+// Apply attributes a validator's ActiveDays in period to one or two owners:
+//   - if no remap applies yet, the original owner keeps all the days;
+//   - if a transfer's EffectiveDay falls within period (its effective round),
+//     the days are split proportionally around that day;
+//   - otherwise, the most recent transfer's owner keeps all the days.
+func (r *Remap) Apply(pubkey string, period rewards.Period, originalOwner common.Address, activeDays int) []Split {
+	entries := r.byPublicKey[pubkey]
+	if len(entries) == 0 {
+		return []Split{{originalOwner, activeDays}}
+	}
+
+	owner := originalOwner
+	for _, entry := range entries {
+		toOwner := common.HexToAddress(entry.ToOwner)
+
+		switch {
+		case time.Time(period).Before(time.Time(entry.EffectivePeriod)):
+			// Not yet effective as of this round.
+			continue
+		case period == entry.EffectivePeriod && entry.EffectiveDay > 0:
+			// Takes effect partway through this round: split proportionally
+			// around the transfer day.
+			totalDays := period.Days()
+			offsetDays := entry.EffectiveDay - 1
+			if offsetDays < 0 {
+				offsetDays = 0
+			} else if offsetDays > totalDays {
+				offsetDays = totalDays
+			}
+			beforeDays := activeDays * offsetDays / totalDays
+			afterDays := activeDays - beforeDays
+
+			var splits []Split
+			if beforeDays > 0 {
+				splits = append(splits, Split{owner, beforeDays})
+			}
+			if afterDays > 0 {
+				splits = append(splits, Split{toOwner, afterDays})
+			}
+			return splits
+		default:
+			// Already in full effect for the whole round (either this is a
+			// later round, or the transfer takes effect from this round's
+			// very first day).
+			owner = toOwner
+		}
+	}
+	return []Split{{owner, activeDays}}
+}
@@ -0,0 +1,99 @@
+package remap
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bloxapp/ssv-rewards/pkg/rewards"
+)
+
+func monthPeriod(year int, month time.Month) rewards.Period {
+	return rewards.Period(time.Date(year, month, 1, 0, 0, 0, 0, time.UTC))
+}
+
+// TestRemapApply exercises the day-split arithmetic directly, independent of
+// any calculateRound caller, so a regression in the split math itself (as
+// opposed to how callers use it) fails here rather than only showing up in a
+// conformance vector.
+func TestRemapApply(t *testing.T) {
+	const pubKey = "0xaa"
+	fromOwner := common.HexToAddress("0x1111")
+	toOwner := common.HexToAddress("0x2222")
+	jan := monthPeriod(2023, time.January)  // 31 days
+	feb := monthPeriod(2023, time.February) // 28 days
+
+	tests := []struct {
+		name   string
+		remaps rewards.OwnerRemaps
+		period rewards.Period
+		want   []Split
+	}{
+		{
+			name:   "no remap for this validator",
+			remaps: nil,
+			period: jan,
+			want:   []Split{{fromOwner, 31}},
+		},
+		{
+			name: "remap not yet effective",
+			remaps: rewards.OwnerRemaps{
+				{PublicKey: pubKey, FromOwner: fromOwner.Hex(), ToOwner: toOwner.Hex(), EffectivePeriod: feb},
+			},
+			period: jan,
+			want:   []Split{{fromOwner, 31}},
+		},
+		{
+			name: "remap already in full effect from a prior round",
+			remaps: rewards.OwnerRemaps{
+				{PublicKey: pubKey, FromOwner: fromOwner.Hex(), ToOwner: toOwner.Hex(), EffectivePeriod: jan},
+			},
+			period: feb,
+			want:   []Split{{toOwner, 28}},
+		},
+		{
+			name: "effective day zero takes effect from the round's first day",
+			remaps: rewards.OwnerRemaps{
+				{PublicKey: pubKey, FromOwner: fromOwner.Hex(), ToOwner: toOwner.Hex(), EffectivePeriod: jan},
+			},
+			period: jan,
+			want:   []Split{{toOwner, 31}},
+		},
+		{
+			name: "effective day mid-round splits proportionally",
+			remaps: rewards.OwnerRemaps{
+				{PublicKey: pubKey, FromOwner: fromOwner.Hex(), ToOwner: toOwner.Hex(), EffectivePeriod: jan, EffectiveDay: 16},
+			},
+			period: jan,
+			want:   []Split{{fromOwner, 15}, {toOwner, 16}},
+		},
+		{
+			name: "effective day one sends the whole round to the new owner",
+			remaps: rewards.OwnerRemaps{
+				{PublicKey: pubKey, FromOwner: fromOwner.Hex(), ToOwner: toOwner.Hex(), EffectivePeriod: jan, EffectiveDay: 1},
+			},
+			period: jan,
+			want:   []Split{{toOwner, 31}},
+		},
+		{
+			name: "effective day on the round's last day leaves almost nothing for the new owner",
+			remaps: rewards.OwnerRemaps{
+				{PublicKey: pubKey, FromOwner: fromOwner.Hex(), ToOwner: toOwner.Hex(), EffectivePeriod: jan, EffectiveDay: 31},
+			},
+			period: jan,
+			want:   []Split{{fromOwner, 30}, {toOwner, 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(tt.remaps)
+			got := r.Apply(pubKey, tt.period, fromOwner, tt.period.Days())
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
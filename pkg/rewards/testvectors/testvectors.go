@@ -0,0 +1,121 @@
+// Package testvectors loads the conformance corpus under
+// pkg/rewards/testvectors/ — self-contained fixtures that exercise the
+// reward calculator's aggregation logic without a live PostgreSQL database.
+package testvectors
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Meta describes what a vector exercises, so failures are easy to triage
+// and the corpus can evolve alongside the plan schema.
+type Meta struct {
+	Description   string `json:"description"`
+	Category      string `json:"category"`
+	SchemaVersion string `json:"schema_version"`
+
+	// ExpectError marks a vector that exercises a calculation failure (e.g.
+	// a round with zero participants) rather than producing expected
+	// artifacts. The runner asserts that calculation fails and skips
+	// artifact comparison.
+	ExpectError bool `json:"expect_error"`
+}
+
+// ValidatorParticipation is a fixture stand-in for a row that would
+// otherwise come from the active_days_by_validator SQL function.
+type ValidatorParticipation struct {
+	OwnerAddress string `json:"ownerAddress"`
+	PublicKey    string `json:"publicKey"`
+	ActiveDays   int    `json:"activeDays"`
+}
+
+// OwnerParticipation is a fixture stand-in for a row that would otherwise
+// come from the active_days_by_owner SQL function.
+type OwnerParticipation struct {
+	OwnerAddress string `json:"ownerAddress"`
+	Validators   int    `json:"validators"`
+	ActiveDays   int    `json:"activeDays"`
+}
+
+// RoundFixture holds the participations for one round of the vector's plan.
+// Rounds are matched to the plan's Rounds by position, in order, so a
+// vector doesn't need to reproduce the Period parsing/formatting logic.
+type RoundFixture struct {
+	Validators []ValidatorParticipation `json:"validators"`
+	Owners     []OwnerParticipation     `json:"owners"`
+}
+
+// Vector is a single conformance test case.
+type Vector struct {
+	Name string
+	Dir  string
+	Meta Meta
+
+	// Plan is the raw rewards.yaml for this vector.
+	Plan []byte
+
+	// Rounds holds one fixture per round in the plan, in plan order.
+	Rounds []RoundFixture
+}
+
+// ExpectedPath returns the path of an expected artifact (e.g.
+// "by-validator.csv") within the vector's expected/ directory.
+func (v *Vector) ExpectedPath(name string) string {
+	return filepath.Join(v.Dir, "expected", name)
+}
+
+// Discover returns every vector directory under root, sorted by name so
+// runs are reproducible.
+func Discover(root string) ([]string, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vectors directory %q: %w", root, err)
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, filepath.Join(root, entry.Name()))
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// Load reads a vector from dir, which must contain meta.json, rewards.yaml
+// and participations.json.
+func Load(dir string) (*Vector, error) {
+	var meta Meta
+	if err := readJSON(filepath.Join(dir, "meta.json"), &meta); err != nil {
+		return nil, fmt.Errorf("failed to read meta.json: %w", err)
+	}
+
+	plan, err := os.ReadFile(filepath.Join(dir, "rewards.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rewards.yaml: %w", err)
+	}
+
+	var rounds []RoundFixture
+	if err := readJSON(filepath.Join(dir, "participations.json"), &rounds); err != nil {
+		return nil, fmt.Errorf("failed to read participations.json: %w", err)
+	}
+
+	return &Vector{
+		Name:   filepath.Base(dir),
+		Dir:    dir,
+		Meta:   meta,
+		Plan:   plan,
+		Rounds: rounds,
+	}, nil
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}